@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// contextKey namespaces values stored in a request's context so they don't collide
+// with keys set by other packages.
+type contextKey string
+
+// ContextKeySession is the key under which bearerAuth stores the caller's TokenInfo.
+const ContextKeySession contextKey = "session"
+
+// tokenTTL is how long a token issued by /login stays valid.
+const tokenTTL = 24 * time.Hour
+
+// TokenInfo is what a verified bearer token tells us about the caller.
+type TokenInfo struct {
+	Subject string
+	Expiry  time.Time
+}
+
+type sessionClaims struct {
+	jwt.StandardClaims
+}
+
+// issueToken signs a JWT identifying username, valid for tokenTTL.
+func (srv *Server) issueToken(username string) (string, error) {
+	claims := sessionClaims{
+		jwt.StandardClaims{
+			Subject:   username,
+			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(srv.creds.Secret))
+}
+
+// parseToken verifies a signed token and extracts its TokenInfo.
+func (srv *Server) parseToken(tokenString string) (*TokenInfo, error) {
+	claims := &sessionClaims{}
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, &httpError{"Unexpected signing method!", http.StatusUnauthorized}
+		}
+		return []byte(srv.creds.Secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenInfo{
+		Subject: claims.Subject,
+		Expiry:  time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+// loginHandler exchanges valid basic-auth credentials for a signed session JWT.
+func loginHandler(srv *Server, w http.ResponseWriter, req *http.Request) {
+	username, password, ok := req.BasicAuth()
+	if !ok || !srv.validateCredentials(username, password) {
+		w.Header().Set("WWW-Authenticate", "Basic")
+		srv.sendError(w, "Unauthorized, invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := srv.issueToken(username)
+	if err != nil {
+		srv.sendError(w, "Error while issuing token!", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte(token))
+}
+
+// bearerAuth is a basicAuth alternative for callers that authenticated once via
+// /login: it verifies the signature and expiry of the Authorization: Bearer
+// token and makes the caller's TokenInfo available to handler via the context.
+func (srv *Server) bearerAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString == "" || tokenString == authHeader {
+			srv.sendError(w, "Unauthorized, missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		info, err := srv.parseToken(tokenString)
+		if err != nil {
+			srv.sendError(w, "Unauthorized, invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+		if info.Expiry.Before(time.Now()) {
+			srv.sendError(w, "Unauthorized, token expired", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ContextKeySession, *info)
+		handler(w, r.WithContext(ctx))
+	}
+}
+
+// requireAuth accepts either HTTP Basic credentials or a bearer token, so bot
+// clients can keep using basic auth while per-user clients switch to JWTs.
+func (srv *Server) requireAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			srv.bearerAuth(handler)(w, r)
+			return
+		}
+		srv.basicAuth(handler)(w, r)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+)
+
+func newTestServer() *Server {
+	creds := credentials{Username: "user", Password: "pass", Secret: "test-secret"}
+	return NewServer(creds, ":0", maxQuestionCount, nil, zap.NewNop())
+}
+
+func TestBearerAuthAcceptsFreshToken(t *testing.T) {
+	srv := newTestServer()
+
+	token, err := srv.issueToken("user")
+	if err != nil {
+		t.Fatalf("issueToken returned error: %v", err)
+	}
+
+	called := false
+	handler := srv.bearerAuth(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		info := r.Context().Value(ContextKeySession).(TokenInfo)
+		if info.Subject != "user" {
+			t.Errorf("expected subject %q, got %q", "user", info.Subject)
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/startGame", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if !called {
+		t.Fatal("expected the wrapped handler to run")
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestBearerAuthRejectsExpiredToken(t *testing.T) {
+	srv := newTestServer()
+
+	claims := sessionClaims{
+		jwt.StandardClaims{
+			Subject:   "user",
+			ExpiresAt: time.Now().Add(-time.Hour).Unix(),
+			IssuedAt:  time.Now().Add(-2 * time.Hour).Unix(),
+		},
+	}
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token, err := expired.SignedString([]byte(srv.creds.Secret))
+	if err != nil {
+		t.Fatalf("failed to sign expired token: %v", err)
+	}
+
+	handler := srv.bearerAuth(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not run for an expired token")
+	})
+
+	req := httptest.NewRequest("GET", "/startGame", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestBearerAuthRejectsForgedToken(t *testing.T) {
+	srv := newTestServer()
+
+	claims := sessionClaims{
+		jwt.StandardClaims{
+			Subject:   "user",
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token, err := forged.SignedString([]byte("not-the-real-secret"))
+	if err != nil {
+		t.Fatalf("failed to sign forged token: %v", err)
+	}
+
+	handler := srv.bearerAuth(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not run for a forged token")
+	})
+
+	req := httptest.NewRequest("GET", "/startGame", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rec.Code)
+	}
+}
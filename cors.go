@@ -0,0 +1,61 @@
+package main
+
+import "net/http"
+
+// addCorsHeader sets the CORS headers a browser-based client needs and answers
+// preflight OPTIONS requests directly, without reaching next.
+func (srv *Server) addCorsHeader(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		// A wildcard only ever allows uncredentialed requests: browsers refuse to
+		// pair Allow-Origin: * with Allow-Credentials, and echoing the caller's
+		// origin for a "*" config would silently turn it into a credentialed one.
+		if srv.isExactlyAllowedOrigin(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		} else if srv.isAllowedOrigin(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		}
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (srv *Server) isAllowedOrigin(origin string) bool {
+	for _, allowed := range srv.creds.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func (srv *Server) isExactlyAllowedOrigin(origin string) bool {
+	for _, allowed := range srv.creds.AllowedOrigins {
+		if allowed != "*" && allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// requireMethod rejects requests that don't use method before handler runs, so
+// the body is never decoded for a request that was going to be rejected anyway.
+func (srv *Server) requireMethod(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			srv.sendError(w, "Method no support!", http.StatusBadRequest)
+			return
+		}
+		handler(w, r)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// loggingConfig controls how NewLogger builds its *zap.Logger: verbosity, where
+// logs are written, and in which format.
+type loggingConfig struct {
+	Level      string
+	Encoding   string // "json" for prod, "console" for dev
+	FilePath   string // empty means stdout, no rotation
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// NewLogger builds a zap.Logger from cfg. When cfg.FilePath is set, output is
+// routed through lumberjack for size-based rotation, compression and retention.
+func NewLogger(cfg loggingConfig) (*zap.Logger, error) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = zapcore.InfoLevel
+	}
+
+	var encoder zapcore.Encoder
+	if cfg.Encoding == "console" {
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	} else {
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	}
+
+	var writer zapcore.WriteSyncer
+	if cfg.FilePath == "" {
+		writer = zapcore.AddSync(os.Stdout)
+	} else {
+		writer = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   cfg.FilePath,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		})
+	}
+
+	return zap.New(zapcore.NewCore(encoder, writer, level)), nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Server (a.k.a. Connector) owns everything a handler needs instead of reaching for
+// package-level globals: the credentials, the routes, the configuration and the
+// in-memory game sessions. Constructing more than one lets unit tests run fully
+// isolated instances side by side.
+type Server struct {
+	creds            credentials
+	mux              *http.ServeMux
+	port             string
+	maxQuestionCount int8
+	store            QuestionStore
+	logger           *zap.Logger
+	sessions         sync.Map
+	pending          sync.Map
+}
+
+// NewServer builds a ready-to-use Server with its own ServeMux.
+func NewServer(creds credentials, port string, maxQuestionCount int8, store QuestionStore, logger *zap.Logger) *Server {
+	return &Server{
+		creds:            creds,
+		mux:              http.NewServeMux(),
+		port:             port,
+		maxQuestionCount: maxQuestionCount,
+		store:            store,
+		logger:           logger,
+	}
+}
+
+// WrapEndpoint adapts a handler that takes an explicit *Server into a plain
+// http.HandlerFunc bound to srv, so handlers no longer need to reach for globals.
+func WrapEndpoint(srv *Server, handler func(*Server, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(srv, w, r)
+	}
+}
+
+func loadCredentials(path string) (credentials, error) {
+	var creds credentials
+
+	jsonFile, err := os.Open(path)
+	if err != nil {
+		return creds, err
+	}
+	defer jsonFile.Close()
+
+	byteValue, err := ioutil.ReadAll(jsonFile)
+	if err != nil {
+		return creds, err
+	}
+
+	if err := json.Unmarshal(byteValue, &creds); err != nil {
+		return creds, err
+	}
+
+	return creds, nil
+}
+
+func (srv *Server) validateCredentials(username, password string) bool {
+	return subtle.ConstantTimeCompare([]byte(srv.creds.Username), []byte(username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(srv.creds.Password), []byte(password)) == 1
+}
+
+func (srv *Server) basicAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+
+		if !ok || !srv.validateCredentials(username, password) {
+			w.Header().Set("WWW-Authenticate", "Basic")
+			srv.sendError(w, "Unauthorized, invalid credentials", http.StatusUnauthorized)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func (srv *Server) bindEndPointsToRoutes() {
+	srv.mux.HandleFunc("/login", srv.addCorsHeader(WrapEndpoint(srv, loginHandler)))
+	srv.mux.HandleFunc("/startGame", srv.addCorsHeader(srv.requireAuth(srv.requireMethod("POST", WrapEndpoint(srv, startGameHandler)))))
+	srv.mux.HandleFunc("/play", srv.addCorsHeader(srv.requireAuth(srv.playHandler)))
+}
+
+// ListenAndServe starts serving srv's routes on srv.port.
+func (srv *Server) ListenAndServe() error {
+	return http.ListenAndServe(srv.port, srv.mux)
+}
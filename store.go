@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"sync"
+)
+
+// Stats summarises how a user has been doing across the questions they were served.
+type Stats struct {
+	Answered int
+	Correct  int
+}
+
+// QuestionStore draws questions for a game and grades the answers given for them.
+// Implementations are responsible for remembering, per user, which question was
+// last handed out so RecordAnswer can grade it.
+type QuestionStore interface {
+	NextQuestion(ctx context.Context, userID string) (*questionResponse, error)
+	RecordAnswer(ctx context.Context, userID, questionID, answer string) (bool, error)
+	Stats(ctx context.Context, userID string) (Stats, error)
+}
+
+var errNoCurrentQuestion = errors.New("no question was served to this user yet")
+
+// newQuestionStore picks a QuestionStore implementation based on driver: "json"
+// loads questionsPath as a JSON-file-backed store, anything else is treated as a
+// database/sql driver name and opened against sqlDSN.
+func newQuestionStore(driver, questionsPath, sqlDSN string) (QuestionStore, error) {
+	if driver == "json" {
+		return NewJSONQuestionStore(questionsPath)
+	}
+	return NewSQLQuestionStore(driver, sqlDSN)
+}
+
+// jsonQuestionStore is a QuestionStore backed by a questions.json file loaded once
+// at startup, with per-user progress kept in memory.
+type jsonQuestionStore struct {
+	questions []questionResponse
+
+	mu       sync.Mutex
+	current  map[string]*questionResponse
+	progress map[string]Stats
+}
+
+// NewJSONQuestionStore loads the question bank from a JSON file, the same way
+// loadCredentials loads config.json.
+func NewJSONQuestionStore(path string) (*jsonQuestionStore, error) {
+	byteValue, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []questionResponse
+	if err := json.Unmarshal(byteValue, &questions); err != nil {
+		return nil, err
+	}
+
+	return &jsonQuestionStore{
+		questions: questions,
+		current:   make(map[string]*questionResponse),
+		progress:  make(map[string]Stats),
+	}, nil
+}
+
+func (s *jsonQuestionStore) NextQuestion(ctx context.Context, userID string) (*questionResponse, error) {
+	if len(s.questions) == 0 {
+		return nil, errors.New("question bank is empty")
+	}
+
+	question := s.questions[rand.Intn(len(s.questions))]
+
+	s.mu.Lock()
+	s.current[userID] = &question
+	s.mu.Unlock()
+
+	return &question, nil
+}
+
+func (s *jsonQuestionStore) RecordAnswer(ctx context.Context, userID, questionID, answer string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	question, ok := s.current[userID]
+	if !ok || question.ID != questionID {
+		return false, errNoCurrentQuestion
+	}
+
+	correct := question.RightAnswer == answer
+	stats := s.progress[userID]
+	stats.Answered++
+	if correct {
+		stats.Correct++
+	}
+	s.progress[userID] = stats
+
+	return correct, nil
+}
+
+func (s *jsonQuestionStore) Stats(ctx context.Context, userID string) (Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.progress[userID], nil
+}
+
+// sqlQuestionStore is a QuestionStore backed by a SQL database reachable through
+// database/sql, using whichever driver the caller registered. Expects a
+// "questions" table (id, question, wrong_answer_1..3, right_answer, image) and an
+// "answers" table (user_id, question_id, answer, correct) to record grading.
+type sqlQuestionStore struct {
+	db *sql.DB
+}
+
+// NewSQLQuestionStore opens a QuestionStore against a SQL database. driverName
+// must have been registered beforehand (e.g. via a blank import chosen at build
+// time), and is typically passed in from a command-line flag.
+func NewSQLQuestionStore(driverName, dataSourceName string) (*sqlQuestionStore, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return &sqlQuestionStore{db: db}, nil
+}
+
+func (s *sqlQuestionStore) NextQuestion(ctx context.Context, userID string) (*questionResponse, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, question, wrong_answer_1, wrong_answer_2, wrong_answer_3, right_answer, image
+		FROM questions
+		ORDER BY RANDOM()
+		LIMIT 1`)
+
+	var q questionResponse
+	if err := row.Scan(&q.ID, &q.Question, &q.WrongAnswer1, &q.WrongAnswer2, &q.WrongAnswer3, &q.RightAnswer, &q.Image); err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// RecordAnswer writes one row per answer, so Stats().Answered counts questions
+// actually answered rather than questions merely served (matching jsonQuestionStore).
+func (s *sqlQuestionStore) RecordAnswer(ctx context.Context, userID, questionID, answer string) (bool, error) {
+	var rightAnswer string
+	if err := s.db.QueryRowContext(ctx, `SELECT right_answer FROM questions WHERE id = ?`, questionID).Scan(&rightAnswer); err != nil {
+		return false, err
+	}
+
+	correct := rightAnswer == answer
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO answers (user_id, question_id, answer, correct)
+		VALUES (?, ?, ?, ?)`, userID, questionID, answer, correct)
+	if err != nil {
+		return false, err
+	}
+
+	return correct, nil
+}
+
+func (s *sqlQuestionStore) Stats(ctx context.Context, userID string) (Stats, error) {
+	var stats Stats
+	row := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN correct THEN 1 ELSE 0 END), 0)
+		FROM answers
+		WHERE user_id = ?`, userID)
+
+	if err := row.Scan(&stats.Answered, &stats.Correct); err != nil {
+		return Stats{}, err
+	}
+
+	return stats, nil
+}
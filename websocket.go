@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Actions making up the /play message protocol.
+const (
+	actionStart    = "start"
+	actionAnswer   = "answer"
+	actionNext     = "next"
+	actionQuestion = "question"
+	actionStats    = "stats"
+	actionError    = "error"
+)
+
+// gameMessage is the wire format exchanged over the /play websocket connection,
+// in both directions.
+type gameMessage struct {
+	Action string `json:"Action"`
+	Value  string `json:"Value,omitempty"`
+}
+
+// gameSession tracks the state of a single in-progress game for one connection.
+// Scoring itself lives in the QuestionStore, keyed by userID, so it survives a
+// reconnect.
+type gameSession struct {
+	userID            string
+	remaining         int8
+	currentQuestionID string
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// playHandler upgrades an authenticated request to a websocket and keeps streaming
+// questions/answers over it until the game ends or the client disconnects.
+func (srv *Server) playHandler(w http.ResponseWriter, req *http.Request) {
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		srv.logger.Warn("Error while upgrading to websocket", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	srv.sessions.Store(conn, &gameSession{})
+	defer srv.sessions.Delete(conn)
+
+	for {
+		var msg gameMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			srv.logger.Info("Closing /play connection", zap.Error(err))
+			return
+		}
+
+		sessionValue, _ := srv.sessions.Load(conn)
+		session := sessionValue.(*gameSession)
+
+		switch msg.Action {
+		case actionStart:
+			srv.handleStart(conn, session, msg)
+		case actionAnswer:
+			srv.handleAnswer(conn, session, msg)
+		case actionNext:
+			srv.sendNextQuestion(conn, session)
+		default:
+			conn.WriteJSON(gameMessage{Action: actionError, Value: "Unknown action"})
+		}
+	}
+}
+
+func (srv *Server) handleStart(conn *websocket.Conn, session *gameSession, msg gameMessage) {
+	session.userID = msg.Value
+	session.remaining = srv.maxQuestionCount
+	if pending, ok := srv.pending.LoadAndDelete(session.userID); ok {
+		session.remaining = pending.(int8)
+	}
+	srv.sendNextQuestion(conn, session)
+}
+
+func (srv *Server) handleAnswer(conn *websocket.Conn, session *gameSession, msg gameMessage) {
+	_, err := srv.store.RecordAnswer(context.Background(), session.userID, session.currentQuestionID, msg.Value)
+	if err != nil {
+		conn.WriteJSON(gameMessage{Action: actionError, Value: "Error While Grading Your Answer!"})
+		return
+	}
+	srv.sendNextQuestion(conn, session)
+}
+
+// sendNextQuestion pushes the next question frame, or a final stats frame once the
+// session has run out of questions.
+func (srv *Server) sendNextQuestion(conn *websocket.Conn, session *gameSession) {
+	if session.remaining <= 0 {
+		stats, err := srv.store.Stats(context.Background(), session.userID)
+		if err != nil {
+			conn.WriteJSON(gameMessage{Action: actionError, Value: "Error While Fetching Your Stats!"})
+			return
+		}
+		conn.WriteJSON(gameMessage{Action: actionStats, Value: fmt.Sprintf("Score: %d/%d", stats.Correct, stats.Answered)})
+		return
+	}
+	session.remaining--
+
+	question, err := srv.store.NextQuestion(context.Background(), session.userID)
+	if err != nil {
+		conn.WriteJSON(gameMessage{Action: actionError, Value: "Error While Fetching The Next Question!"})
+		return
+	}
+	session.currentQuestionID = question.ID
+
+	payload, _ := json.Marshal(question)
+	conn.WriteJSON(gameMessage{Action: actionQuestion, Value: string(payload)})
+}